@@ -0,0 +1,180 @@
+package replay
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	caCertFileName = "ca-cert.pem"
+	caKeyFileName  = "ca-key.pem"
+)
+
+// CertAuthority signs per-host leaf certificates on the fly so a
+// RecordingTransport-backed proxy can terminate TLS for hosts it has
+// never seen before. The CA cert/key are persisted to disk so the same
+// authority can be trusted across runs instead of regenerated each time.
+type CertAuthority struct {
+	dir     string
+	cert    *x509.Certificate
+	key     *rsa.PrivateKey
+	certPEM []byte
+
+	mu    sync.Mutex
+	cache map[string]*tls.Certificate
+}
+
+// NewCertAuthority loads a CA from dir if one was persisted there by a
+// previous run, otherwise generates a new one and saves it to dir.
+func NewCertAuthority(dir string) (ca *CertAuthority, err error) {
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		err = errors.Wrapf(err, "failed to create CA directory %s", dir)
+		return
+	}
+
+	ca = &CertAuthority{dir: dir, cache: make(map[string]*tls.Certificate)}
+
+	certPath := filepath.Join(dir, caCertFileName)
+	keyPath := filepath.Join(dir, caKeyFileName)
+
+	if _, statErr := os.Stat(certPath); statErr == nil {
+		err = ca.load(certPath, keyPath)
+		return
+	}
+
+	err = ca.generate(certPath, keyPath)
+	return
+}
+
+func (ca *CertAuthority) load(certPath, keyPath string) error {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read CA cert %s", certPath)
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read CA key %s", keyPath)
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse CA cert")
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	key, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse CA key")
+	}
+
+	ca.cert = cert
+	ca.key = key
+	ca.certPEM = certPEM
+	return nil
+}
+
+func (ca *CertAuthority) generate(certPath, keyPath string) error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate CA key")
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "replay recording proxy CA", Organization: []string{"replay"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return errors.Wrap(err, "failed to create CA certificate")
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse generated CA certificate")
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	if err = os.WriteFile(certPath, certPEM, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write CA cert %s", certPath)
+	}
+	if err = os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write CA key %s", keyPath)
+	}
+
+	ca.cert = cert
+	ca.key = key
+	ca.certPEM = certPEM
+	return nil
+}
+
+// CertPEM returns the PEM-encoded CA certificate so it can be installed
+// in a client's trust store.
+func (ca *CertAuthority) CertPEM() []byte {
+	return ca.certPEM
+}
+
+// LeafCertFor returns a TLS certificate for host, signed by ca and
+// cached so repeated CONNECTs to the same host reuse the same leaf.
+func (ca *CertAuthority) LeafCertFor(host string) (*tls.Certificate, error) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+
+	if cert, ok := ca.cache[host]; ok {
+		return cert, nil
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate leaf key")
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	// CONNECT targets are frequently IP literals (tests, internal
+	// deployments); Go's TLS client only honors IPAddresses SANs for
+	// those, not DNSNames.
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to sign leaf certificate for %s", host)
+	}
+
+	cert := &tls.Certificate{
+		Certificate: [][]byte{der, ca.cert.Raw},
+		PrivateKey:  key,
+	}
+	ca.cache[host] = cert
+	return cert, nil
+}
@@ -0,0 +1,160 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const grpcContentTypePrefix = "application/grpc"
+
+func isGRPCRequest(req *http.Request) bool {
+	return strings.HasPrefix(req.Header.Get("Content-Type"), grpcContentTypePrefix)
+}
+
+// grpcFrame is a single gRPC length-prefixed message: a 1-byte
+// compression flag, a 4-byte big-endian length, and the message bytes.
+type grpcFrame struct {
+	Compressed bool
+	Data       []byte
+}
+
+// parseGRPCFrames splits a gRPC request/response body into its
+// individual length-prefixed messages, so a raw-dump hash of the whole
+// body (which would also capture any partial/chunked framing) isn't
+// needed to compare payloads.
+func parseGRPCFrames(body []byte) (frames []grpcFrame) {
+	for len(body) >= 5 {
+		compressed := body[0] == 1
+		length := binary.BigEndian.Uint32(body[1:5])
+		if uint64(len(body)-5) < uint64(length) {
+			break
+		}
+		frames = append(frames, grpcFrame{Compressed: compressed, Data: body[5 : 5+length]})
+		body = body[5+length:]
+	}
+	return
+}
+
+// GRPCMatcher matches gRPC requests (detected via the application/grpc
+// content type prefix) by full method path and framed message payload.
+// It deliberately ignores headers like grpc-timeout and user-agent,
+// which vary per call and would defeat ExactHashMatcher's raw dump
+// hash. When built with a FileDescriptorSet, payloads are decoded
+// against the method's input type and compared as canonical JSON, so
+// two proto encodings of the same semantic message still match.
+type GRPCMatcher struct {
+	methods map[string]protoreflect.MessageType
+}
+
+// NewGRPCMatcher builds a GRPCMatcher. fds may be nil, in which case
+// messages are compared as raw framed bytes.
+func NewGRPCMatcher(fds *descriptorpb.FileDescriptorSet) (*GRPCMatcher, error) {
+	m := &GRPCMatcher{methods: make(map[string]protoreflect.MessageType)}
+	if fds == nil {
+		return m, nil
+	}
+
+	files, err := protodesc.NewFiles(fds)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build proto file registry")
+	}
+
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		services := fd.Services()
+		for i := 0; i < services.Len(); i++ {
+			service := services.Get(i)
+			methods := service.Methods()
+			for j := 0; j < methods.Len(); j++ {
+				method := methods.Get(j)
+				fullMethod := "/" + string(service.FullName()) + "/" + string(method.Name())
+				m.methods[fullMethod] = dynamicpb.NewMessageType(method.Input())
+			}
+		}
+		return true
+	})
+
+	return m, nil
+}
+
+func (m *GRPCMatcher) Match(req *http.Request, entry Entry) (score int, ok bool) {
+	if !isGRPCRequest(req) {
+		return
+	}
+
+	entryReq := entry.Request.Factory()
+	if !isGRPCRequest(entryReq) {
+		return
+	}
+	if req.URL.Path != entryReq.URL.Path {
+		return
+	}
+
+	reqBody, err := readAndRestore(req)
+	if err != nil {
+		return
+	}
+
+	reqFrames := parseGRPCFrames(reqBody)
+	entryFrames := parseGRPCFrames([]byte(entry.Request.PostData.Text))
+	if len(reqFrames) == 0 || len(reqFrames) != len(entryFrames) {
+		return
+	}
+
+	msgType, hasDescriptor := m.methods[req.URL.Path]
+
+	for i := range reqFrames {
+		if !hasDescriptor {
+			if !bytes.Equal(reqFrames[i].Data, entryFrames[i].Data) {
+				return
+			}
+			continue
+		}
+
+		reqJSON, err := canonicalProtoJSON(msgType, reqFrames[i].Data)
+		if err != nil {
+			return
+		}
+		entryJSON, err := canonicalProtoJSON(msgType, entryFrames[i].Data)
+		if err != nil {
+			return
+		}
+		if reqJSON != entryJSON {
+			return
+		}
+	}
+
+	return 1, true
+}
+
+func canonicalProtoJSON(msgType protoreflect.MessageType, data []byte) (string, error) {
+	msg := msgType.New().Interface()
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return "", err
+	}
+	jsonData, err := protojson.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	return canonicalizeJSON(jsonData)
+}
+
+// trailersFromGRPCStatus builds the `_trailers` HAR extension for a
+// gRPC response, so replay can reconstruct grpc-status/grpc-message
+// instead of requiring every recorded entry to carry them as ordinary
+// headers.
+func trailersFromGRPCStatus(status, message string) Headers {
+	return Headers{
+		{Name: "grpc-status", Value: status},
+		{Name: "grpc-message", Value: message},
+	}
+}
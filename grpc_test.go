@@ -0,0 +1,176 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func grpcFrameBytes(payload []byte) []byte {
+	header := make([]byte, 5)
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func grpcBody(payload []byte) io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(grpcFrameBytes(payload)))
+}
+
+func pingFileDescriptorSet() *descriptorpb.FileDescriptorSet {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("ping.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Ping"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("message"),
+						Number:   proto.Int32(1),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+						JsonName: proto.String("message"),
+					},
+					{
+						Name:     proto.String("code"),
+						Number:   proto.Int32(2),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(),
+						JsonName: proto.String("code"),
+					},
+				},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("Echo"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       proto.String("Say"),
+						InputType:  proto.String(".test.Ping"),
+						OutputType: proto.String(".test.Ping"),
+					},
+				},
+			},
+		},
+	}
+	return &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}}
+}
+
+func grpcEntry(postData []byte) Entry {
+	return Entry{
+		Request: Request{
+			Method:   "POST",
+			Url:      "https://example.com/test.Echo/Say",
+			Headers:  Headers{{Name: "Content-Type", Value: "application/grpc"}},
+			PostData: PostData{Text: string(grpcFrameBytes(postData))},
+		},
+		Response: Response{Status: 200, StatusText: "OK"},
+	}
+}
+
+func grpcRequest(body []byte) *http.Request {
+	return &http.Request{
+		Method: "POST",
+		URL:    &url.URL{Scheme: "https", Host: "example.com", Path: "/test.Echo/Say"},
+		Host:   "example.com",
+		Header: http.Header{"Content-Type": []string{"application/grpc"}},
+		Body:   grpcBody(body),
+	}
+}
+
+func TestGRPCMatcher_RawBytes(t *testing.T) {
+	matcher, err := NewGRPCMatcher(nil)
+	require.NoError(t, err)
+
+	entry := grpcEntry([]byte("same-payload"))
+
+	_, ok := matcher.Match(grpcRequest([]byte("same-payload")), entry)
+	require.True(t, ok)
+
+	_, ok = matcher.Match(grpcRequest([]byte("different-payload")), entry)
+	require.False(t, ok)
+}
+
+// fieldOrderedMessage hand-encodes a Ping message with message/code
+// fields written in the given order, so two wire-compatible encodings
+// of the same semantic value differ byte-for-byte.
+func fieldOrderedMessage(message string, code int32, messageFirst bool) []byte {
+	var data []byte
+	writeMessage := func() {
+		data = protowire.AppendTag(data, 1, protowire.BytesType)
+		data = protowire.AppendString(data, message)
+	}
+	writeCode := func() {
+		data = protowire.AppendTag(data, 2, protowire.VarintType)
+		data = protowire.AppendVarint(data, uint64(code))
+	}
+	if messageFirst {
+		writeMessage()
+		writeCode()
+	} else {
+		writeCode()
+		writeMessage()
+	}
+	return data
+}
+
+func TestGRPCMatcher_WithProtoDescriptor(t *testing.T) {
+	matcher, err := NewGRPCMatcher(pingFileDescriptorSet())
+	require.NoError(t, err)
+
+	entry := grpcEntry(fieldOrderedMessage("hello", 7, true))
+	req := grpcRequest(fieldOrderedMessage("hello", 7, false))
+
+	_, ok := matcher.Match(req, entry)
+	require.True(t, ok)
+
+	req = grpcRequest(fieldOrderedMessage("goodbye", 7, false))
+	_, ok = matcher.Match(req, entry)
+	require.False(t, ok)
+}
+
+// TestReplayTransport_GRPCWithProtoDescriptorAndCustomMatcher ensures
+// WithProtoDescriptor's descriptor-aware GRPCMatcher still applies when
+// the caller also configures other matchers via WithMatchers, instead
+// of being silently dropped by the default-chain bootstrap.
+func TestReplayTransport_GRPCWithProtoDescriptorAndCustomMatcher(t *testing.T) {
+	entry := grpcEntry(fieldOrderedMessage("hello", 7, true))
+
+	rt, err := NewReplayTransport(
+		WithProtoDescriptor(pingFileDescriptorSet()),
+		WithMatchers(MethodPathMatcher{}),
+	)
+	require.NoError(t, err)
+	require.NoError(t, rt.cacheEntry(entry))
+
+	req := grpcRequest(fieldOrderedMessage("hello", 7, false))
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestReplayTransport_GRPCDefaultMatching(t *testing.T) {
+	entry := grpcEntry([]byte("hello"))
+	entry.Response.Trailers = trailersFromGRPCStatus("0", "")
+
+	rt, err := NewReplayTransport()
+	require.NoError(t, err)
+	require.NoError(t, rt.cacheEntry(entry))
+
+	req := grpcRequest([]byte("hello"))
+	req.Header.Set("grpc-timeout", "10S")
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, "0", resp.Trailer.Get("grpc-status"))
+}
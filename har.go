@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
@@ -124,12 +125,16 @@ type Response struct {
 	Headers     Headers     `json:"headers"`
 	HttpVersion string      `json:"httpVersion"`
 	RedirectURL string      `json:"redirectURL"`
+	// Trailers is a non-standard HAR extension carrying HTTP trailers
+	// (e.g. a gRPC response's grpc-status/grpc-message) that HAR 1.2 has
+	// no field for.
+	Trailers Headers `json:"_trailers,omitempty"`
 }
 
 func (r Response) Factory() *http.Response {
 	body, _ := base64.StdEncoding.DecodeString(r.Content.Text)
 	major, minor, _ := http.ParseHTTPVersion(r.HttpVersion)
-	return &http.Response{
+	resp := &http.Response{
 		Status:        r.StatusText,
 		StatusCode:    r.Status,
 		Proto:         r.HttpVersion,
@@ -139,6 +144,10 @@ func (r Response) Factory() *http.Response {
 		Body:          io.NopCloser(bytes.NewBuffer(body)),
 		ContentLength: int64(len(body)),
 	}
+	if len(r.Trailers) > 0 {
+		resp.Trailer = r.Trailers.ToHTTPHeader()
+	}
+	return resp
 }
 
 type Entry struct {
@@ -163,6 +172,32 @@ type Entry struct {
 	StartedDateTime   time.Time `json:"startedDateTime"`
 }
 
+// FactoryWith builds the entry's response like Response.Factory, but
+// runs it through filters first, passing along the request that
+// triggered replay so dynamic bodies (see ResponseBodyFilter,
+// WithResponseTemplate) can be generated per call instead of a single
+// body being replayed byte-for-byte every time.
+func (e Entry) FactoryWith(req *http.Request, filters ...ResponseBodyFilter) *http.Response {
+	resp := e.Response.Factory()
+	if len(filters) == 0 {
+		return resp
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	for _, filter := range filters {
+		body = filter(e, req, body)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewBuffer(body))
+	resp.ContentLength = int64(len(body))
+	// Filters (e.g. WithResponseTemplate) routinely change the body size
+	// from what was recorded, so the header must be kept in sync too -
+	// callers that trust resp.Header directly (a reverse proxy, a
+	// manual header copy) would otherwise truncate or desync the body.
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return resp
+}
+
 func LoadHarFile(harFilePath string) (har *HarFile, err error) {
 	har = new(HarFile)
 	f, err := os.Open(harFilePath)
@@ -0,0 +1,165 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Matcher decides whether an incoming request should be answered by
+// entry, returning a score for ranking against other entries the same
+// Matcher accepts. ReplayTransport tries each configured Matcher in
+// order and, on the first one that accepts any entry, picks the
+// highest-scoring match.
+type Matcher interface {
+	Match(req *http.Request, entry Entry) (score int, ok bool)
+}
+
+// ExactHashMatcher reproduces ReplayTransport's original behavior:
+// entries match only when HashRequest agrees byte-for-byte (after
+// filters) with the incoming request.
+type ExactHashMatcher struct {
+	filters []RequestFilter
+}
+
+func NewExactHashMatcher(filters ...RequestFilter) *ExactHashMatcher {
+	return &ExactHashMatcher{filters: filters}
+}
+
+func (m *ExactHashMatcher) Match(req *http.Request, entry Entry) (score int, ok bool) {
+	reqHash, err := HashRequest(req, m.filters...)
+	if err != nil {
+		return
+	}
+	entryHash, err := HashRequest(entry.Request.Factory(), m.filters...)
+	if err != nil {
+		return
+	}
+	if reqHash == entryHash {
+		return 1, true
+	}
+	return
+}
+
+// MethodPathMatcher matches on HTTP method and URL path alone, ignoring
+// headers, query string, and body.
+type MethodPathMatcher struct{}
+
+func (MethodPathMatcher) Match(req *http.Request, entry Entry) (score int, ok bool) {
+	entryReq := entry.Request.Factory()
+	if req.Method == entryReq.Method && req.URL.Path == entryReq.URL.Path {
+		return 1, true
+	}
+	return
+}
+
+// JSONBodyMatcher matches when both bodies parse as JSON and are
+// semantically equal, so key ordering and whitespace don't break
+// replay for requests that re-encode equivalent JSON.
+type JSONBodyMatcher struct{}
+
+func (JSONBodyMatcher) Match(req *http.Request, entry Entry) (score int, ok bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	reqBody, err := readAndRestore(req)
+	if err != nil {
+		return
+	}
+
+	reqCanon, err := canonicalizeJSON(reqBody)
+	if err != nil {
+		return
+	}
+
+	entryCanon, err := canonicalizeJSON([]byte(entry.Request.PostData.Text))
+	if err != nil {
+		return
+	}
+
+	if reqCanon == entryCanon {
+		return 1, true
+	}
+	return
+}
+
+func canonicalizeJSON(data []byte) (string, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return "", err
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// QueryParamSubsetMatcher matches when every query parameter recorded
+// on entry is also present, with the same values, on the incoming
+// request. The incoming request may carry additional parameters the
+// entry didn't record. An entry with no recorded query parameters never
+// matches.
+type QueryParamSubsetMatcher struct{}
+
+func (QueryParamSubsetMatcher) Match(req *http.Request, entry Entry) (score int, ok bool) {
+	entryQuery := entry.Request.QueryString.ToURLValues()
+	if len(entryQuery) == 0 {
+		return
+	}
+
+	reqQuery := req.URL.Query()
+	for key, values := range entryQuery {
+		for _, value := range values {
+			if !containsString(reqQuery[key], value) {
+				return
+			}
+		}
+	}
+	return len(entryQuery), true
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// HeaderMatcher matches when the named headers have identical values on
+// the incoming request and the recorded entry. A HeaderMatcher built
+// with no header names never matches.
+type HeaderMatcher struct {
+	keys []string
+}
+
+func NewHeaderMatcher(keys ...string) *HeaderMatcher {
+	return &HeaderMatcher{keys: keys}
+}
+
+func (m *HeaderMatcher) Match(req *http.Request, entry Entry) (score int, ok bool) {
+	if len(m.keys) == 0 {
+		return
+	}
+
+	entryReq := entry.Request.Factory()
+	for _, key := range m.keys {
+		if req.Header.Get(key) != entryReq.Header.Get(key) {
+			return
+		}
+	}
+	return len(m.keys), true
+}
+
+func readAndRestore(req *http.Request) ([]byte, error) {
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
@@ -0,0 +1,89 @@
+package replay
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testEntry() Entry {
+	return Entry{
+		Request: Request{
+			Method: "POST",
+			Url:    "https://example.com/v1/resource?id=1",
+			QueryString: QueryParams{
+				{Name: "id", Value: "1"},
+			},
+			Headers: Headers{
+				{Name: "X-Api-Version", Value: "2022-01-01"},
+			},
+			PostData: PostData{Text: `{"foo":"bar"}`},
+		},
+		Response: Response{Status: 200, StatusText: "OK"},
+	}
+}
+
+func TestMethodPathMatcher(t *testing.T) {
+	entry := testEntry()
+	req := &http.Request{Method: "POST", URL: &url.URL{Path: "/v1/resource"}}
+
+	score, ok := MethodPathMatcher{}.Match(req, entry)
+	require.True(t, ok)
+	require.Equal(t, 1, score)
+
+	req.Method = "GET"
+	_, ok = MethodPathMatcher{}.Match(req, entry)
+	require.False(t, ok)
+}
+
+func TestJSONBodyMatcher(t *testing.T) {
+	entry := testEntry()
+	req := &http.Request{Body: io.NopCloser(bytes.NewBufferString(`{ "foo" : "bar" }`))}
+
+	_, ok := JSONBodyMatcher{}.Match(req, entry)
+	require.True(t, ok)
+}
+
+func TestQueryParamSubsetMatcher(t *testing.T) {
+	entry := testEntry()
+	req := &http.Request{URL: &url.URL{RawQuery: "id=1&extra=ignored"}}
+
+	_, ok := QueryParamSubsetMatcher{}.Match(req, entry)
+	require.True(t, ok)
+
+	req.URL.RawQuery = "id=2"
+	_, ok = QueryParamSubsetMatcher{}.Match(req, entry)
+	require.False(t, ok)
+}
+
+func TestHeaderMatcher(t *testing.T) {
+	entry := testEntry()
+	req := &http.Request{Header: http.Header{"X-Api-Version": []string{"2022-01-01"}}}
+
+	_, ok := NewHeaderMatcher("X-Api-Version").Match(req, entry)
+	require.True(t, ok)
+
+	req.Header.Set("X-Api-Version", "2023-01-01")
+	_, ok = NewHeaderMatcher("X-Api-Version").Match(req, entry)
+	require.False(t, ok)
+}
+
+func TestReplayTransport_WithMatchers(t *testing.T) {
+	rt, err := NewReplayTransport(WithMatchers(MethodPathMatcher{}))
+	require.NoError(t, err)
+	require.NoError(t, rt.cacheEntry(testEntry()))
+
+	req := &http.Request{
+		Method: "POST",
+		URL:    &url.URL{Scheme: "https", Host: "example.com", Path: "/v1/resource"},
+		Header: http.Header{},
+	}
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+}
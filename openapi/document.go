@@ -0,0 +1,90 @@
+package openapi
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+type Document struct {
+	OpenAPI    string              `json:"openapi" yaml:"openapi"`
+	Paths      map[string]PathItem `json:"paths" yaml:"paths"`
+	Components Components          `json:"components" yaml:"components"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas" yaml:"schemas"`
+}
+
+type PathItem map[string]Operation
+
+type Operation struct {
+	OperationId string              `json:"operationId" yaml:"operationId"`
+	Parameters  []Parameter         `json:"parameters" yaml:"parameters"`
+	Responses   map[string]Response `json:"responses" yaml:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name" yaml:"name"`
+	In       string  `json:"in" yaml:"in"`
+	Required bool    `json:"required" yaml:"required"`
+	Schema   *Schema `json:"schema" yaml:"schema"`
+}
+
+type Response struct {
+	Description string               `json:"description" yaml:"description"`
+	Content     map[string]MediaType `json:"content" yaml:"content"`
+}
+
+type MediaType struct {
+	Schema   *Schema        `json:"schema" yaml:"schema"`
+	Example  any            `json:"example" yaml:"example"`
+	Examples map[string]any `json:"examples" yaml:"examples"`
+}
+
+type Schema struct {
+	Ref        string             `json:"$ref" yaml:"$ref"`
+	Type       string             `json:"type" yaml:"type"`
+	Format     string             `json:"format" yaml:"format"`
+	Enum       []any              `json:"enum" yaml:"enum"`
+	Items      *Schema            `json:"items" yaml:"items"`
+	Properties map[string]*Schema `json:"properties" yaml:"properties"`
+	Required   []string           `json:"required" yaml:"required"`
+}
+
+// LoadSpec reads an OpenAPI 3 document from disk, choosing a YAML or JSON
+// decoder based on the file extension (.yaml/.yml vs .json).
+func LoadSpec(path string) (doc *Document, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read OpenAPI spec %s", path)
+		return
+	}
+
+	doc = new(Document)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, doc)
+	default:
+		err = yaml.Unmarshal(data, doc)
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "failed to decode OpenAPI spec %s", path)
+	}
+	return
+}
+
+func (d *Document) resolveSchema(s *Schema) *Schema {
+	if s == nil || s.Ref == "" {
+		return s
+	}
+	name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+	if resolved, ok := d.Components.Schemas[name]; ok {
+		return resolved
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+)
+
+func (m *Matcher) exampleBody(media MediaType) []byte {
+	if media.Example != nil {
+		data, err := json.Marshal(media.Example)
+		if err == nil {
+			return data
+		}
+	}
+
+	names := make([]string, 0, len(media.Examples))
+	for name := range media.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data, err := json.Marshal(media.Examples[name])
+		if err == nil {
+			return data
+		}
+	}
+
+	if media.Schema != nil {
+		value := m.exampleFromSchema(media.Schema)
+		data, err := json.Marshal(value)
+		if err == nil {
+			return data
+		}
+	}
+
+	return nil
+}
+
+// maxSchemaDepth bounds how deep exampleFromSchema will recurse into
+// object properties/array items, so a self-referencing schema (a
+// Category with children []Category, a linked list, a comment thread)
+// bottoms out instead of recursing forever.
+const maxSchemaDepth = 16
+
+// exampleFromSchema walks schema, resolving $refs against the document,
+// and synthesizes a plausible value: the first enum value when present,
+// a zero value for primitives, or a recursively generated object/array.
+func (m *Matcher) exampleFromSchema(schema *Schema) any {
+	return m.exampleFromSchemaAt(schema, 0)
+}
+
+func (m *Matcher) exampleFromSchemaAt(schema *Schema, depth int) any {
+	if depth > maxSchemaDepth {
+		return nil
+	}
+
+	schema = m.doc.resolveSchema(schema)
+	if schema == nil {
+		return nil
+	}
+
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+
+	switch schema.Type {
+	case "object":
+		obj := make(map[string]any, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			obj[name] = m.exampleFromSchemaAt(prop, depth+1)
+		}
+		return obj
+	case "array":
+		return []any{m.exampleFromSchemaAt(schema.Items, depth+1)}
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return false
+	case "string":
+		return ""
+	default:
+		return nil
+	}
+}
+
+func parseStatus(code string) (int, error) {
+	return strconv.Atoi(code)
+}
+
+func newBody(data []byte) io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(data))
+}
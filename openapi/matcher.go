@@ -0,0 +1,181 @@
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// route is a compiled (method, path template) pair from the spec, used to
+// find the operation that should answer an incoming request.
+type route struct {
+	method    string
+	pattern   *regexp.Regexp
+	operation Operation
+}
+
+var templateParam = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// Matcher routes http.Requests to OpenAPI operations by method and path
+// template, independent of the hash-based matching ReplayTransport uses
+// for its HAR cache.
+type Matcher struct {
+	doc    *Document
+	routes []route
+}
+
+// NewMatcher compiles every operation in doc into a route the matcher can
+// test requests against.
+func NewMatcher(doc *Document) *Matcher {
+	m := &Matcher{doc: doc}
+	for path, item := range doc.Paths {
+		pattern := compilePathTemplate(path)
+		for method, op := range item {
+			m.routes = append(m.routes, route{
+				method:    strings.ToUpper(method),
+				pattern:   pattern,
+				operation: op,
+			})
+		}
+	}
+	return m
+}
+
+func compilePathTemplate(path string) *regexp.Regexp {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	last := 0
+	for _, loc := range templateParam.FindAllStringSubmatchIndex(path, -1) {
+		pattern.WriteString(regexp.QuoteMeta(path[last:loc[0]]))
+		name := path[loc[2]:loc[3]]
+		pattern.WriteString("(?P<" + name + ">[^/]+)")
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(path[last:]))
+	pattern.WriteString("$")
+
+	return regexp.MustCompile(pattern.String())
+}
+
+// Match finds the operation whose method and path template satisfy req,
+// and whose required parameters are all present. It returns false if no
+// operation matches.
+func (m *Matcher) Match(req *http.Request) (op Operation, ok bool) {
+	for _, r := range m.routes {
+		if r.method != req.Method {
+			continue
+		}
+		if !r.pattern.MatchString(req.URL.Path) {
+			continue
+		}
+		if !m.hasRequiredParams(r, req) {
+			continue
+		}
+		return r.operation, true
+	}
+	return
+}
+
+func (m *Matcher) hasRequiredParams(r route, req *http.Request) bool {
+	names := r.pattern.SubexpNames()
+	matches := r.pattern.FindStringSubmatch(req.URL.Path)
+	pathValues := make(map[string]string)
+	for i, name := range names {
+		if name == "" || i >= len(matches) {
+			continue
+		}
+		pathValues[name] = matches[i]
+	}
+
+	for _, param := range r.operation.Parameters {
+		if !param.Required {
+			continue
+		}
+		switch param.In {
+		case "path":
+			if _, ok := pathValues[param.Name]; !ok {
+				return false
+			}
+		case "query":
+			if req.URL.Query().Get(param.Name) == "" {
+				return false
+			}
+		case "header":
+			if req.Header.Get(param.Name) == "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Synthesize builds an *http.Response for op from its first 2xx response
+// definition. The body is the media type's literal example if present,
+// otherwise its first named example (by name), otherwise a value
+// generated from its schema.
+func (m *Matcher) Synthesize(op Operation) *http.Response {
+	status, resp := firstSuccessResponse(op)
+
+	header := make(http.Header)
+	var body []byte
+
+	if contentType, ok := firstContentType(resp); ok {
+		header.Set("Content-Type", contentType)
+		body = m.exampleBody(resp.Content[contentType])
+	}
+
+	return &http.Response{
+		Status:        http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          newBody(body),
+		ContentLength: int64(len(body)),
+	}
+}
+
+func firstSuccessResponse(op Operation) (status int, resp Response) {
+	status = http.StatusOK
+	if r, ok := op.Responses["200"]; ok {
+		return status, r
+	}
+
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			if parsed, err := parseStatus(code); err == nil {
+				return parsed, op.Responses[code]
+			}
+		}
+	}
+	return
+}
+
+// firstContentType picks resp's Content-Type deterministically: a
+// literal application/json entry if present, otherwise the
+// lexicographically first, so Synthesize doesn't depend on Go's
+// randomized map iteration order.
+func firstContentType(resp Response) (contentType string, ok bool) {
+	if _, exists := resp.Content["application/json"]; exists {
+		return "application/json", true
+	}
+
+	types := make([]string, 0, len(resp.Content))
+	for ct := range resp.Content {
+		types = append(types, ct)
+	}
+	if len(types) == 0 {
+		return "", false
+	}
+	sort.Strings(types)
+	return types[0], true
+}
@@ -0,0 +1,115 @@
+package openapi
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testDoc() *Document {
+	return &Document{
+		Paths: map[string]PathItem{
+			"/pets/{petId}": {
+				"get": Operation{
+					OperationId: "getPet",
+					Parameters: []Parameter{
+						{Name: "petId", In: "path", Required: true},
+					},
+					Responses: map[string]Response{
+						"200": {
+							Content: map[string]MediaType{
+								"application/json": {
+									Schema: &Schema{
+										Type: "object",
+										Properties: map[string]*Schema{
+											"name":   {Type: "string"},
+											"status": {Type: "string", Enum: []any{"available", "sold"}},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestMatcher_Match(t *testing.T) {
+	m := NewMatcher(testDoc())
+
+	req := &http.Request{Method: "GET", URL: &url.URL{Path: "/pets/123"}}
+	op, ok := m.Match(req)
+	require.True(t, ok)
+	require.Equal(t, "getPet", op.OperationId)
+
+	req = &http.Request{Method: "POST", URL: &url.URL{Path: "/pets/123"}}
+	_, ok = m.Match(req)
+	require.False(t, ok)
+}
+
+func TestMatcher_Synthesize(t *testing.T) {
+	m := NewMatcher(testDoc())
+	req := &http.Request{Method: "GET", URL: &url.URL{Path: "/pets/123"}}
+	op, ok := m.Match(req)
+	require.True(t, ok)
+
+	resp := m.Synthesize(op)
+	require.Equal(t, 200, resp.StatusCode)
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+}
+
+// TestMatcher_SynthesizeDeterministic guards against picking a
+// non-literal "200" success response or a non-JSON content type at
+// random across runs, since both op.Responses and a Response's Content
+// are Go maps with randomized iteration order.
+func TestMatcher_SynthesizeDeterministic(t *testing.T) {
+	op := Operation{
+		Responses: map[string]Response{
+			"201": {
+				Content: map[string]MediaType{
+					"text/plain":       {Schema: &Schema{Type: "string"}},
+					"application/json": {Schema: &Schema{Type: "object"}},
+				},
+			},
+			"400": {
+				Content: map[string]MediaType{"application/json": {Schema: &Schema{Type: "object"}}},
+			},
+		},
+	}
+
+	m := &Matcher{}
+	for i := 0; i < 20; i++ {
+		resp := m.Synthesize(op)
+		require.Equal(t, 201, resp.StatusCode)
+		require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	}
+}
+
+// TestExampleFromSchema_SelfReferencing guards against stack overflow
+// when a schema refers back to itself (e.g. a Category with a
+// children []Category field), which is valid and common in OpenAPI
+// specs for tree/linked-list/comment-thread shapes.
+func TestExampleFromSchema_SelfReferencing(t *testing.T) {
+	category := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"name":     {Type: "string"},
+			"children": {Type: "array", Items: &Schema{Ref: "#/components/schemas/Category"}},
+		},
+	}
+	doc := &Document{Components: Components{Schemas: map[string]*Schema{"Category": category}}}
+	m := &Matcher{doc: doc}
+
+	var value any
+	require.NotPanics(t, func() {
+		value = m.exampleFromSchema(category)
+	})
+
+	obj, ok := value.(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "", obj["name"])
+}
@@ -0,0 +1,179 @@
+package replay
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+type RecordingProxyParams struct {
+	// OutputFile is where recorded entries are flushed as a HAR 1.2 file.
+	OutputFile string
+	// CADir is where the MITM CA cert/key are persisted between runs.
+	CADir string
+	// BaseTransport is used to dial upstream; defaults to
+	// http.DefaultTransport when nil. Override it to trust a test or
+	// internal upstream's certificate.
+	BaseTransport  http.RoundTripper
+	RequestFilters []RequestFilter
+	Logger         zap.Logger
+}
+
+// NewRecordingProxy runs as an http.Handler/httputil.ReverseProxy that
+// records every request/response pair it forwards into a HAR file. HTTPS
+// traffic is intercepted by terminating TLS with an on-the-fly CA
+// (generated or loaded from CADir) and re-establishing a TLS connection
+// to the real upstream, so recorded HTTPS sessions can be replayed by
+// WithHarFile just like plain HTTP ones.
+func NewRecordingProxy(params RecordingProxyParams) (http.Handler, *RecordingTransport, error) {
+	opts := []RecordingOption{WithRecordingOutputFile(params.OutputFile)}
+	if params.BaseTransport != nil {
+		opts = append(opts, WithRecordingBaseTransport(params.BaseTransport))
+	}
+	for _, filter := range params.RequestFilters {
+		opts = append(opts, WithRecordingRequestFilter(filter))
+	}
+
+	rt, err := NewRecordingTransport(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ca, err := NewCertAuthority(params.CADir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger := params.Logger
+	if logger.Core() == nil {
+		logger = *zap.NewNop()
+	}
+
+	reverse := &httputil.ReverseProxy{
+		Transport: rt,
+		Director: func(req *http.Request) {
+			if req.URL.Scheme == "" {
+				req.URL.Scheme = "http"
+			}
+			if req.URL.Host == "" {
+				req.URL.Host = req.Host
+			}
+		},
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodConnect {
+			serveConnect(w, r, ca, reverse, logger)
+			return
+		}
+		reverse.ServeHTTP(w, r)
+	})
+
+	return handler, rt, nil
+}
+
+func serveConnect(w http.ResponseWriter, r *http.Request, ca *CertAuthority, reverse *httputil.ReverseProxy, logger zap.Logger) {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "proxy does not support hijacking", http.StatusInternalServerError)
+		return
+	}
+
+	clientConn, _, err := hijacker.Hijack()
+	if err != nil {
+		logger.Error("failed to hijack connection", zap.Error(err))
+		return
+	}
+	defer func() { _ = clientConn.Close() }()
+
+	if _, err = clientConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		logger.Error("failed to ack CONNECT", zap.Error(err))
+		return
+	}
+
+	leaf, err := ca.LeafCertFor(host)
+	if err != nil {
+		logger.Error("failed to mint leaf certificate", zap.Error(err), zap.String("host", host))
+		return
+	}
+
+	tlsConn := tls.Server(clientConn, &tls.Config{Certificates: []tls.Certificate{*leaf}})
+	defer func() { _ = tlsConn.Close() }()
+
+	if err = tlsConn.Handshake(); err != nil {
+		logger.Error("TLS handshake with client failed", zap.Error(err), zap.String("host", host))
+		return
+	}
+
+	listener := newSingleConnListener(tlsConn)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req.URL.Scheme = "https"
+			req.URL.Host = r.Host
+			reverse.ServeHTTP(w, req)
+		}),
+	}
+	// Serve blocks until the single connection handed out by Accept is
+	// closed (see singleConnListener), so the deferred tlsConn/clientConn
+	// closes above don't race the goroutine http.Server spawned to serve
+	// it.
+	_ = server.Serve(listener)
+}
+
+// singleConnListener adapts a single already-accepted net.Conn into a
+// net.Listener so http.Server can drive the decrypted MITM connection.
+// http.Server.Serve hands the first Accept'd conn to its own goroutine
+// and immediately calls Accept again, so the second call blocks on done
+// until that goroutine closes the connection, keeping Serve from
+// returning (and the caller from tearing down the conn) early.
+type singleConnListener struct {
+	conn   net.Conn
+	done   chan struct{}
+	served bool
+}
+
+func newSingleConnListener(conn net.Conn) *singleConnListener {
+	return &singleConnListener{conn: conn, done: make(chan struct{})}
+}
+
+func (l *singleConnListener) Accept() (net.Conn, error) {
+	if l.served {
+		<-l.done
+		return nil, io.EOF
+	}
+	l.served = true
+	return &doneNotifyingConn{Conn: l.conn, done: l.done}, nil
+}
+
+func (l *singleConnListener) Close() error {
+	return nil
+}
+
+func (l *singleConnListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+// doneNotifyingConn closes done the first time Close is called, so
+// singleConnListener's second Accept call can unblock once http.Server
+// is finished with the connection.
+type doneNotifyingConn struct {
+	net.Conn
+	done chan struct{}
+	once sync.Once
+}
+
+func (c *doneNotifyingConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() { close(c.done) })
+	return err
+}
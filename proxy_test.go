@@ -0,0 +1,68 @@
+package replay
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewRecordingProxy_MITMConnect drives a real CONNECT tunnel end to
+// end: an http.Client proxies an HTTPS request through NewRecordingProxy,
+// which intercepts TLS with a freshly minted CA and forwards to a real
+// TLS upstream. This exercises the hijacked-connection lifecycle
+// (singleConnListener) and IP-literal leaf certs together, since
+// httptest.NewTLSServer's upstream is addressed by IP.
+func TestNewRecordingProxy_MITMConnect(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer upstream.Close()
+
+	upstreamPool := x509.NewCertPool()
+	upstreamPool.AddCert(upstream.Certificate())
+
+	caDir := t.TempDir()
+	handler, rt, err := NewRecordingProxy(RecordingProxyParams{
+		OutputFile:    filepath.Join(t.TempDir(), "recorded.har"),
+		CADir:         caDir,
+		BaseTransport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: upstreamPool}},
+	})
+	require.NoError(t, err)
+
+	proxyServer := httptest.NewServer(handler)
+	defer proxyServer.Close()
+
+	ca, err := NewCertAuthority(caDir)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(ca.CertPEM()))
+
+	proxyURL, err := url.Parse(proxyServer.URL)
+	require.NoError(t, err)
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		},
+	}
+
+	resp, err := client.Get(upstream.URL)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(body))
+
+	require.Len(t, rt.Entries(), 1)
+}
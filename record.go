@@ -0,0 +1,196 @@
+package replay
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type RecordingOption func(*RecordingTransport) error
+
+// RecordingTransport is the sibling of ReplayTransport: instead of
+// answering requests from a HAR cache, it forwards them to a real
+// upstream and records the request/response pair as a HAR entry,
+// closing the loop so a recorded session can be replayed directly by
+// WithHarFile without a separate conversion step.
+type RecordingTransport struct {
+	base           http.RoundTripper
+	requestFilters []RequestFilter
+	outputPath     string
+
+	mu  sync.Mutex
+	har *HarFile
+}
+
+func NewRecordingTransport(opts ...RecordingOption) (rt *RecordingTransport, err error) {
+	rt = &RecordingTransport{
+		base: http.DefaultTransport,
+		har: &HarFile{
+			Log: Log{
+				Version: "1.2",
+				Creator: Creator{Name: "replay", Version: "1.0"},
+			},
+		},
+	}
+	for _, opt := range opts {
+		if err = opt(rt); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func WithRecordingOutputFile(path string) RecordingOption {
+	return func(rt *RecordingTransport) error {
+		rt.outputPath = path
+		return nil
+	}
+}
+
+func WithRecordingBaseTransport(base http.RoundTripper) RecordingOption {
+	return func(rt *RecordingTransport) error {
+		rt.base = base
+		return nil
+	}
+}
+
+func WithRecordingRequestFilter(filter RequestFilter) RecordingOption {
+	return func(rt *RecordingTransport) error {
+		rt.requestFilters = append(rt.requestFilters, filter)
+		return nil
+	}
+}
+
+func (rt *RecordingTransport) RoundTrip(req *http.Request) (response *http.Response, err error) {
+	started := time.Now()
+
+	reqCopy := CloneRequestWithBody(req)
+	for _, filter := range rt.requestFilters {
+		filter(reqCopy)
+	}
+	var reqBody []byte
+	if reqCopy.Body != nil {
+		reqBody, _ = io.ReadAll(reqCopy.Body)
+	}
+
+	sent := time.Now()
+	response, err = rt.base.RoundTrip(req)
+	if err != nil {
+		return
+	}
+	received := time.Now()
+
+	respCopy := CloneResponseWithBody(response)
+	var respBody []byte
+	if respCopy.Body != nil {
+		respBody, _ = io.ReadAll(respCopy.Body)
+	}
+
+	entry := rt.buildEntry(reqCopy, reqBody, respCopy, respBody, started, sent, received)
+
+	rt.mu.Lock()
+	rt.har.Log.Entries = append(rt.har.Log.Entries, entry)
+	rt.mu.Unlock()
+
+	if rt.outputPath != "" {
+		if flushErr := rt.Flush(); flushErr != nil {
+			err = flushErr
+		}
+	}
+	return
+}
+
+func (rt *RecordingTransport) buildEntry(req *http.Request, reqBody []byte, resp *http.Response, respBody []byte, started, sent, received time.Time) Entry {
+	return Entry{
+		StartedDateTime: started,
+		Time:            float64(received.Sub(started).Milliseconds()),
+		IsHTTPS:         req.URL.Scheme == "https",
+		Timings: Timing{
+			Send:    int(sent.Sub(started).Milliseconds()),
+			Wait:    int(received.Sub(sent).Milliseconds()),
+			Receive: 0,
+		},
+		Request:  requestToHarRequest(req, reqBody),
+		Response: responseToHarResponse(resp, respBody),
+	}
+}
+
+func requestToHarRequest(req *http.Request, body []byte) Request {
+	return Request{
+		Method:      req.Method,
+		Url:         req.URL.String(),
+		HttpVersion: req.Proto,
+		Headers:     headersFromHTTP(req.Header),
+		QueryString: queryParamsFromURL(req.URL),
+		PostData: PostData{
+			Text:     string(body),
+			MimeType: req.Header.Get("Content-Type"),
+		},
+		BodySize: len(body),
+	}
+}
+
+func responseToHarResponse(resp *http.Response, body []byte) Response {
+	return Response{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HttpVersion: resp.Proto,
+		Headers:     headersFromHTTP(resp.Header),
+		Content: ContentType{
+			Size:     len(body),
+			MimeType: resp.Header.Get("Content-Type"),
+			Encoding: "base64",
+			Text:     base64.StdEncoding.EncodeToString(body),
+		},
+		BodySize: len(body),
+	}
+}
+
+func headersFromHTTP(h http.Header) (headers Headers) {
+	for name, values := range h {
+		for _, value := range values {
+			headers = append(headers, Header{Name: name, Value: value})
+		}
+	}
+	return
+}
+
+func queryParamsFromURL(u *url.URL) (params QueryParams) {
+	for name, values := range u.Query() {
+		for _, value := range values {
+			params = append(params, QueryParam{Name: name, Value: value})
+		}
+	}
+	return
+}
+
+// Flush persists every entry recorded so far to the RecordingTransport's
+// output path as a HAR 1.2 file.
+func (rt *RecordingTransport) Flush() error {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	data, err := json.MarshalIndent(rt.har, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal recorded HAR")
+	}
+
+	if err = os.WriteFile(rt.outputPath, data, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write HAR file %s", rt.outputPath)
+	}
+	return nil
+}
+
+// Entries returns the HAR entries recorded so far.
+func (rt *RecordingTransport) Entries() []Entry {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.har.Log.Entries
+}
@@ -0,0 +1,63 @@
+package replay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingTransport_RoundTrip(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer upstream.Close()
+
+	outputFile := filepath.Join(t.TempDir(), "recorded.har")
+	rt, err := NewRecordingTransport(WithRecordingOutputFile(outputFile))
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: rt}
+	resp, err := client.Get(upstream.URL + "/hello?foo=bar")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	entries := rt.Entries()
+	require.Len(t, entries, 1)
+	require.Equal(t, "GET", entries[0].Request.Method)
+	require.Equal(t, 200, entries[0].Response.Status)
+	require.Equal(t, "bar", entries[0].Request.QueryString.ToURLValues().Get("foo"))
+
+	require.FileExists(t, outputFile)
+
+	har, err := LoadHarFile(outputFile)
+	require.NoError(t, err)
+	require.Len(t, har.Log.Entries, 1)
+
+	replayed, err := NewReplayTransport(WithHarFile(outputFile))
+	require.NoError(t, err)
+	replayResp, err := replayed.NewClient().Do(har.Log.Entries[0].Request.Factory())
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, replayResp.StatusCode)
+}
+
+func TestCertAuthority_PersistsAndSignsLeaf(t *testing.T) {
+	dir := t.TempDir()
+
+	ca, err := NewCertAuthority(dir)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(dir, caCertFileName))
+	require.FileExists(t, filepath.Join(dir, caKeyFileName))
+
+	leaf, err := ca.LeafCertFor("example.com")
+	require.NoError(t, err)
+	require.NotNil(t, leaf)
+
+	reloaded, err := NewCertAuthority(dir)
+	require.NoError(t, err)
+	require.Equal(t, ca.CertPEM(), reloaded.CertPEM())
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"io/fs"
 	"net"
 	"net/http"
@@ -21,6 +22,9 @@ import (
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
 	"github.com/sergi/go-diff/diffmatchpatch"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/sourcec0de/replay/openapi"
 )
 
 type RequestFilter func(r *http.Request)
@@ -33,11 +37,20 @@ type SingleResponseTransport struct {
 }
 
 type ReplayTransport struct {
-	harFiles         map[string]*HarFile
-	harResponseCache map[string]Entry
-	requestFilters   []RequestFilter
-	responseFilters  []ResponseFilter
-	debugger         func(key string, request *http.Request) error
+	harFiles        map[string]*HarFile
+	entries         []Entry
+	matchers        []Matcher
+	requestFilters  []RequestFilter
+	responseFilters []ResponseFilter
+	debugger        func(key string, request *http.Request) error
+	openapiMatcher  *openapi.Matcher
+	grpcMatcher     *GRPCMatcher
+
+	sequenced      bool
+	sequencedCache map[string][]Entry
+	cursors        map[string]int
+
+	responseBodyFilters []ResponseBodyFilter
 }
 
 func (r *ReplayTransport) NewClient() *http.Client {
@@ -58,14 +71,20 @@ func (r *ReplayTransport) RoundTrip(request *http.Request) (response *http.Respo
 		filter(request)
 	}
 
-	hashKey, err := HashRequest(request, r.requestFilters...)
-	if err != nil {
-		err = errors.Wrap(err, "failed to hash request")
+	hashKey, hashErr := HashRequest(request, r.requestFilters...)
+	if hashErr != nil {
+		err = errors.Wrap(hashErr, "failed to hash request")
 		return
 	}
 
-	entry, ok := r.harResponseCache[hashKey]
+	entry, ok := r.match(request)
 	if !ok {
+		if r.openapiMatcher != nil {
+			if op, opOk := r.openapiMatcher.Match(request); opOk {
+				response = r.openapiMatcher.Synthesize(op)
+				return
+			}
+		}
 
 		if r.debugger != nil {
 			if dbErr := r.debugger(hashKey, request); dbErr != nil {
@@ -82,19 +101,101 @@ func (r *ReplayTransport) RoundTrip(request *http.Request) (response *http.Respo
 		return
 	}
 
-	response = entry.Response.Factory()
+	if r.sequenced {
+		if seqKey, seqErr := HashRequest(entry.Request.Factory(), r.requestFilters...); seqErr == nil {
+			if seqEntry, seqOk := r.nextSequencedEntry(seqKey); seqOk {
+				entry = seqEntry
+			}
+		}
+	}
+
+	response = entry.FactoryWith(request, r.responseBodyFilters...)
 	return
 }
 
+// match runs each configured Matcher, in order, against every cached
+// entry. The first Matcher that produces at least one match wins;
+// among its matches, the highest-scoring entry is returned. This lets
+// callers order matchers from most to least specific (e.g. exact hash
+// before a loose method+path match) instead of relying on a single
+// hash-keyed lookup.
+func (r *ReplayTransport) match(request *http.Request) (best Entry, matched bool) {
+	var bodyBytes []byte
+	if request.Body != nil && request.Body != http.NoBody {
+		bodyBytes, _ = io.ReadAll(request.Body)
+	}
+	resetBody := func() {
+		if bodyBytes != nil {
+			request.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+		}
+	}
+	defer resetBody()
+
+	bestScore := -1
+	for _, matcher := range r.matchers {
+		found := false
+		for _, entry := range r.entries {
+			resetBody()
+			score, ok := matcher.Match(request, entry)
+			if ok && score > bestScore {
+				bestScore = score
+				best = entry
+				found = true
+			}
+		}
+		if found {
+			return best, true
+		}
+	}
+	return best, false
+}
+
 func (r *ReplayTransport) cacheEntry(entry Entry) error {
+	r.entries = append(r.entries, entry)
+
 	hashKey, err := HashRequest(entry.Request.Factory(), r.requestFilters...)
 	if err != nil {
 		return err
 	}
-	r.harResponseCache[hashKey] = entry
+	r.sequencedCache[hashKey] = append(r.sequencedCache[hashKey], entry)
 	return nil
 }
 
+// nextSequencedEntry returns the next entry, in HAR order, recorded
+// under hashKey, advancing that key's cursor. hashKey is the hash of
+// whichever entry r.match chose for the incoming request, not the
+// incoming request itself, so a loose Matcher (e.g. MethodPathMatcher)
+// still steps through the right FIFO sequence instead of only matching
+// byte-identical replays. Once the cursor reaches the last entry for a
+// key it sticks there, so repeated calls past the end of a recorded
+// sequence keep returning the final state.
+func (r *ReplayTransport) nextSequencedEntry(hashKey string) (entry Entry, ok bool) {
+	entries, exists := r.sequencedCache[hashKey]
+	if !exists || len(entries) == 0 {
+		return
+	}
+
+	cursor := r.cursors[hashKey]
+	if cursor >= len(entries) {
+		cursor = len(entries) - 1
+	}
+	entry = entries[cursor]
+
+	if cursor < len(entries)-1 {
+		cursor++
+	}
+	r.cursors[hashKey] = cursor
+
+	return entry, true
+}
+
+// Reset rewinds every sequenced-response cursor back to the start of
+// its recorded sequence, so a ReplayTransport can be reused across
+// tests without replaying stale state from an earlier test.
+func (r *ReplayTransport) Reset() {
+	r.cursors = make(map[string]int)
+}
+
 func RequestToBuff(r *http.Request, filters ...RequestFilter) *bytes.Buffer {
 	request := CloneRequestWithBody(r)
 	for _, filter := range filters {
@@ -168,16 +269,86 @@ func WithHarDir(dirname string) ReplayOption {
 	}
 }
 
+func WithOpenAPISpec(path string) ReplayOption {
+	return func(transport *ReplayTransport) error {
+		doc, err := openapi.LoadSpec(path)
+		if err != nil {
+			return err
+		}
+		transport.openapiMatcher = openapi.NewMatcher(doc)
+		return nil
+	}
+}
+
+func WithMatcher(m Matcher) ReplayOption {
+	return func(transport *ReplayTransport) error {
+		transport.matchers = append(transport.matchers, m)
+		return nil
+	}
+}
+
+func WithMatchers(matchers ...Matcher) ReplayOption {
+	return func(transport *ReplayTransport) error {
+		transport.matchers = append(transport.matchers, matchers...)
+		return nil
+	}
+}
+
+func WithResponseBodyFilter(filter ResponseBodyFilter) ReplayOption {
+	return func(transport *ReplayTransport) error {
+		transport.responseBodyFilters = append(transport.responseBodyFilters, filter)
+		return nil
+	}
+}
+
+// WithProtoDescriptor enables descriptor-aware gRPC matching: recorded
+// and incoming message payloads are decoded against fds and compared as
+// canonical JSON instead of raw bytes, so two proto encodings of the
+// same semantic message still replay correctly.
+func WithProtoDescriptor(fds *descriptorpb.FileDescriptorSet) ReplayOption {
+	return func(transport *ReplayTransport) error {
+		matcher, err := NewGRPCMatcher(fds)
+		if err != nil {
+			return err
+		}
+		transport.grpcMatcher = matcher
+		return nil
+	}
+}
+
+func WithSequencedResponses() ReplayOption {
+	return func(transport *ReplayTransport) error {
+		transport.sequenced = true
+		return nil
+	}
+}
+
 func NewReplayTransport(opts ...ReplayOption) (rt *ReplayTransport, err error) {
 	rt = &ReplayTransport{
-		harFiles:         make(map[string]*HarFile),
-		harResponseCache: make(map[string]Entry),
+		harFiles:       make(map[string]*HarFile),
+		sequencedCache: make(map[string][]Entry),
+		cursors:        make(map[string]int),
 	}
 	for _, opt := range opts {
 		if err = opt(rt); err != nil {
 			return
 		}
 	}
+
+	switch {
+	case rt.grpcMatcher != nil:
+		// WithProtoDescriptor must always take effect, even when the
+		// caller also configured other matchers via WithMatcher/
+		// WithMatchers, so prepend it directly instead of only
+		// participating in the no-options default below.
+		rt.matchers = append([]Matcher{rt.grpcMatcher}, rt.matchers...)
+		if len(rt.matchers) == 1 {
+			rt.matchers = append(rt.matchers, NewExactHashMatcher(rt.requestFilters...))
+		}
+	case len(rt.matchers) == 0:
+		grpcMatcher, _ := NewGRPCMatcher(nil)
+		rt.matchers = []Matcher{grpcMatcher, NewExactHashMatcher(rt.requestFilters...)}
+	}
 	return
 }
 
@@ -210,8 +381,9 @@ func WithRoundTripDebugger() ReplayOption {
 			var output = new(strings.Builder)
 			incoming := RequestToBuff(request, transport.requestFilters...).String()
 
-			for cacheKey, entry := range transport.harResponseCache {
+			for _, entry := range transport.entries {
 				cached := RequestToBuff(entry.Request.Factory(), transport.requestFilters...).String()
+				cacheKey, _ := HashRequest(entry.Request.Factory(), transport.requestFilters...)
 				diff := differ.DiffMain(cached, incoming, true)
 
 				_, _ = fmt.Fprintf(output,
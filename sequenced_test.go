@@ -0,0 +1,93 @@
+package replay
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sequencedEntry(body string) Entry {
+	return Entry{
+		Request: Request{Method: "GET", Url: "https://example.com/v1/resource"},
+		Response: Response{
+			Status:     200,
+			StatusText: "OK",
+			Content:    ContentType{Text: base64.StdEncoding.EncodeToString([]byte(body)), Encoding: "base64"},
+		},
+	}
+}
+
+func TestReplayTransport_SequencedResponses(t *testing.T) {
+	rt, err := NewReplayTransport(WithSequencedResponses())
+	require.NoError(t, err)
+
+	require.NoError(t, rt.cacheEntry(sequencedEntry("first")))
+	require.NoError(t, rt.cacheEntry(sequencedEntry("second")))
+
+	req := func() *http.Request {
+		return &http.Request{
+			Method: "GET",
+			URL:    &url.URL{Scheme: "https", Host: "example.com", Path: "/v1/resource"},
+			Header: http.Header{},
+		}
+	}
+
+	resp, err := rt.RoundTrip(req())
+	require.NoError(t, err)
+	require.Equal(t, "first", string(mustReadAll(t, resp)))
+
+	resp, err = rt.RoundTrip(req())
+	require.NoError(t, err)
+	require.Equal(t, "second", string(mustReadAll(t, resp)))
+
+	// sequence exhausted: sticks on the last recorded entry
+	resp, err = rt.RoundTrip(req())
+	require.NoError(t, err)
+	require.Equal(t, "second", string(mustReadAll(t, resp)))
+
+	rt.Reset()
+
+	resp, err = rt.RoundTrip(req())
+	require.NoError(t, err)
+	require.Equal(t, "first", string(mustReadAll(t, resp)))
+}
+
+// TestReplayTransport_SequencedResponsesWithLooseMatcher pairs sequenced
+// responses with a loose Matcher (rather than the default exact hash),
+// so incoming requests that vary per call (a different X-Request-Id
+// header each time, say) still step through the recorded FIFO sequence
+// instead of each one only ever returning the first cached entry.
+func TestReplayTransport_SequencedResponsesWithLooseMatcher(t *testing.T) {
+	rt, err := NewReplayTransport(WithMatchers(MethodPathMatcher{}), WithSequencedResponses())
+	require.NoError(t, err)
+
+	require.NoError(t, rt.cacheEntry(sequencedEntry("first")))
+	require.NoError(t, rt.cacheEntry(sequencedEntry("second")))
+
+	req := func(requestID string) *http.Request {
+		return &http.Request{
+			Method: "GET",
+			URL:    &url.URL{Scheme: "https", Host: "example.com", Path: "/v1/resource"},
+			Header: http.Header{"X-Request-Id": []string{requestID}},
+		}
+	}
+
+	resp, err := rt.RoundTrip(req("a"))
+	require.NoError(t, err)
+	require.Equal(t, "first", string(mustReadAll(t, resp)))
+
+	resp, err = rt.RoundTrip(req("b"))
+	require.NoError(t, err)
+	require.Equal(t, "second", string(mustReadAll(t, resp)))
+}
+
+func mustReadAll(t *testing.T, resp *http.Response) []byte {
+	t.Helper()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return data
+}
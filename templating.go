@@ -0,0 +1,98 @@
+package replay
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ResponseBodyFilter rewrites a recorded response body before it's
+// returned to the caller, given the HAR entry it came from and the
+// request that triggered it. Filters run in registration order.
+type ResponseBodyFilter func(entry Entry, req *http.Request, body []byte) []byte
+
+// TemplateRequest exposes the triggering request to a response body
+// template.
+type TemplateRequest struct {
+	URL    *url.URL
+	Header http.Header
+	JSON   any
+}
+
+// TemplateContext is the data available to a WithResponseTemplate body:
+// the triggering request, the current time, and a fresh UUID, so
+// recorded IDs/timestamps/echoed fields can be made to vary per replay
+// instead of going stale.
+type TemplateContext struct {
+	Request TemplateRequest
+	Now     time.Time
+	UUID    string
+}
+
+func newTemplateContext(req *http.Request) (TemplateContext, error) {
+	uuid, err := newUUID()
+	if err != nil {
+		return TemplateContext{}, err
+	}
+
+	ctx := TemplateContext{
+		Request: TemplateRequest{URL: req.URL, Header: req.Header},
+		Now:     time.Now(),
+		UUID:    uuid,
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		if body, readErr := readAndRestore(req); readErr == nil {
+			var parsed any
+			if json.Unmarshal(body, &parsed) == nil {
+				ctx.Request.JSON = parsed
+			}
+		}
+	}
+
+	return ctx, nil
+}
+
+// WithResponseTemplate runs text/template over every recorded response
+// body, giving it access to the triggering request via TemplateContext.
+// It is implemented as a ResponseBodyFilter so it composes with any
+// filters registered via WithResponseBodyFilter.
+func WithResponseTemplate() ReplayOption {
+	return WithResponseBodyFilter(renderResponseTemplate)
+}
+
+func renderResponseTemplate(_ Entry, req *http.Request, body []byte) []byte {
+	tmpl, err := template.New("response").Parse(string(body))
+	if err != nil {
+		return body
+	}
+
+	ctx, err := newTemplateContext(req)
+	if err != nil {
+		return body
+	}
+
+	var rendered bytes.Buffer
+	if err = tmpl.Execute(&rendered, ctx); err != nil {
+		return body
+	}
+
+	return rendered.Bytes()
+}
+
+func newUUID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, "failed to generate UUID")
+	}
+	buf[6] = (buf[6] & 0x0f) | 0x40
+	buf[8] = (buf[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:16]), nil
+}
@@ -0,0 +1,115 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithResponseTemplate(t *testing.T) {
+	entry := Entry{
+		Request: Request{Method: "GET", Url: "https://example.com/v1/resource"},
+		Response: Response{
+			Status:     200,
+			StatusText: "OK",
+			Content: ContentType{
+				Encoding: "base64",
+				Text:     base64.StdEncoding.EncodeToString([]byte(`{"echo":"{{.Request.Header.Get "X-Echo"}}"}`)),
+			},
+		},
+	}
+
+	rt, err := NewReplayTransport(WithMatchers(MethodPathMatcher{}), WithResponseTemplate())
+	require.NoError(t, err)
+	require.NoError(t, rt.cacheEntry(entry))
+
+	req := &http.Request{
+		Method: "GET",
+		URL:    &url.URL{Scheme: "https", Host: "example.com", Path: "/v1/resource"},
+		Header: http.Header{"X-Echo": []string{"hi"}},
+	}
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, `{"echo":"hi"}`, string(body))
+}
+
+// TestWithResponseTemplate_ContentLengthHeaderStaysConsistent guards
+// against the rendered body's length drifting from the recorded
+// Content-Length header: templating routinely changes body size (a
+// UUID is longer than the literal it replaces), and a caller that
+// trusts the header directly would truncate or desync the body.
+func TestWithResponseTemplate_ContentLengthHeaderStaysConsistent(t *testing.T) {
+	entry := Entry{
+		Request: Request{Method: "GET", Url: "https://example.com/v1/resource"},
+		Response: Response{
+			Status:     200,
+			StatusText: "OK",
+			Headers:    Headers{{Name: "Content-Length", Value: "2"}},
+			Content: ContentType{
+				Encoding: "base64",
+				Text:     base64.StdEncoding.EncodeToString([]byte(`{{.UUID}}`)),
+			},
+		},
+	}
+
+	rt, err := NewReplayTransport(WithMatchers(MethodPathMatcher{}), WithResponseTemplate())
+	require.NoError(t, err)
+	require.NoError(t, rt.cacheEntry(entry))
+
+	req := &http.Request{
+		Method: "GET",
+		URL:    &url.URL{Scheme: "https", Host: "example.com", Path: "/v1/resource"},
+		Header: http.Header{},
+	}
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	require.Len(t, body, 36)
+	require.Equal(t, strconv.Itoa(len(body)), resp.Header.Get("Content-Length"))
+	require.Equal(t, int64(len(body)), resp.ContentLength)
+}
+
+func TestWithResponseBodyFilter(t *testing.T) {
+	entry := Entry{
+		Request: Request{Method: "GET", Url: "https://example.com/v1/resource"},
+		Response: Response{
+			Status:     200,
+			StatusText: "OK",
+			Content:    ContentType{Encoding: "base64", Text: base64.StdEncoding.EncodeToString([]byte("original"))},
+		},
+	}
+
+	rt, err := NewReplayTransport(WithMatchers(MethodPathMatcher{}), WithResponseBodyFilter(func(_ Entry, _ *http.Request, body []byte) []byte {
+		return bytes.ToUpper(body)
+	}))
+	require.NoError(t, err)
+	require.NoError(t, rt.cacheEntry(entry))
+
+	req := &http.Request{
+		Method: "GET",
+		URL:    &url.URL{Scheme: "https", Host: "example.com", Path: "/v1/resource"},
+		Header: http.Header{},
+	}
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "ORIGINAL", string(body))
+}